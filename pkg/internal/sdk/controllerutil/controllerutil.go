@@ -0,0 +1,85 @@
+// Package controllerutil provides helpers that are shared by the helm
+// operator's reconcilers but that don't belong in any particular one of
+// them: polling for deletion, finalizer bookkeeping, and reasoning about
+// whether a native Kubernetes owner reference can be used between two
+// objects.
+package controllerutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WaitForDeletion waits for obj to be deleted from the cluster, polling with
+// client c until it observes a NotFound error or ctx is done.
+func WaitForDeletion(ctx context.Context, c client.Client, obj runtime.Object) error {
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		return err
+	}
+	return wait.PollImmediateUntil(time.Millisecond*100, func() (bool, error) {
+		err := c.Get(ctx, key, obj)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}, ctx.Done())
+}
+
+// SupportsOwnerReference returns true if dependent can carry a native
+// metadata.ownerReferences entry pointing at owner. Kubernetes garbage
+// collection only honors owner references when the owner is cluster-scoped,
+// or when both owner and dependent are namespace-scoped and share the same
+// namespace; any other combination (e.g. a namespace-scoped owner with a
+// cluster-scoped dependent, or a dependent in a different namespace) is
+// rejected by the API server, so callers must fall back to some other
+// ownership bookkeeping in that case.
+func SupportsOwnerReference(rm meta.RESTMapper, owner, dependent runtime.Object) (bool, error) {
+	ownerGVK := owner.GetObjectKind().GroupVersionKind()
+	dependentGVK := dependent.GetObjectKind().GroupVersionKind()
+
+	ownerMapping, err := rm.RESTMapping(ownerGVK.GroupKind(), ownerGVK.Version)
+	if err != nil {
+		return false, fmt.Errorf("get REST mapping for owner %s: %v", ownerGVK, err)
+	}
+	dependentMapping, err := rm.RESTMapping(dependentGVK.GroupKind(), dependentGVK.Version)
+	if err != nil {
+		return false, fmt.Errorf("get REST mapping for dependent %s: %v", dependentGVK, err)
+	}
+
+	ownerAccessor, err := meta.Accessor(owner)
+	if err != nil {
+		return false, fmt.Errorf("get accessor for owner: %v", err)
+	}
+	dependentAccessor, err := meta.Accessor(dependent)
+	if err != nil {
+		return false, fmt.Errorf("get accessor for dependent: %v", err)
+	}
+
+	if ownerMapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return true, nil
+	}
+	if dependentMapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return false, nil
+	}
+	return ownerAccessor.GetNamespace() == dependentAccessor.GetNamespace(), nil
+}
+
+// ContainsFinalizer returns true if finalizer is present in obj's finalizer
+// list.
+func ContainsFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package controllerutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var gcLog = logf.Log.WithName("controllerutil").WithName("DependentGarbageCollector")
+
+const (
+	defaultGCResyncPeriod     = 5 * time.Minute
+	defaultGCChunkSize        = int64(500)
+	defaultGCNegativeCacheTTL = time.Minute
+)
+
+// DependentGarbageCollector periodically sweeps dependents that were adopted
+// via SetOwnerLabels, because SupportsOwnerReference determined they
+// couldn't carry a native owner reference, and deletes the ones whose owner
+// no longer exists. Kubernetes' built-in garbage collector can't do this for
+// us since it only acts on metadata.ownerReferences.
+type DependentGarbageCollector struct {
+	// Client is used to list dependents and look up owners.
+	Client           client.Client
+	ResyncPeriod     time.Duration
+	ChunkSize        int64
+	NegativeCacheTTL time.Duration
+
+	mu      sync.Mutex
+	started map[watchedGVKPair]bool
+}
+
+// watchedGVKPair identifies a single (owner GVK, dependent GVK) sweep
+// started by Start. Two different owner GVKs adopting the same dependent
+// Kind must each get their own sweep goroutine, so both GVKs are part of the
+// key.
+type watchedGVKPair struct {
+	ownerGVK, dependentGVK schema.GroupVersionKind
+}
+
+// NewDependentGarbageCollector returns a DependentGarbageCollector with the
+// package's default resync period, chunk size, and negative cache TTL.
+func NewDependentGarbageCollector(c client.Client) *DependentGarbageCollector {
+	return &DependentGarbageCollector{
+		Client:           c,
+		ResyncPeriod:     defaultGCResyncPeriod,
+		ChunkSize:        defaultGCChunkSize,
+		NegativeCacheTTL: defaultGCNegativeCacheTTL,
+	}
+}
+
+// Start launches a goroutine that sweeps dependentGVK objects owned by
+// ownerGVK every ResyncPeriod, until ctx is done. It is idempotent: calling
+// Start again for an (ownerGVK, dependentGVK) pair that is already being
+// watched is a no-op, so reconcilers can call it on every reconcile without
+// leaking goroutines.
+func (g *DependentGarbageCollector) Start(ctx context.Context, ownerGVK, dependentGVK schema.GroupVersionKind) {
+	pair := watchedGVKPair{ownerGVK: ownerGVK, dependentGVK: dependentGVK}
+
+	g.mu.Lock()
+	if g.started == nil {
+		g.started = map[watchedGVKPair]bool{}
+	}
+	if g.started[pair] {
+		g.mu.Unlock()
+		return
+	}
+	g.started[pair] = true
+	g.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(g.ResyncPeriod)
+		defer ticker.Stop()
+		cache := negativeCache{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := g.Sweep(ctx, ownerGVK, dependentGVK, cache); err != nil {
+					gcLog.Error(err, "sweep failed", "ownerGVK", ownerGVK, "dependentGVK", dependentGVK)
+				}
+			}
+		}
+	}()
+}
+
+// ownerRef identifies the owner recorded in a dependent's owner labels.
+type ownerRef struct {
+	Namespace string
+	Name      string
+	UID       string
+}
+
+// negativeCache remembers owners that were recently confirmed missing, so
+// later sweeps run by the same Start goroutine skip the owner Get for them
+// until NegativeCacheTTL has passed. Within one sweep each owner is looked up
+// once anyway, since dependents from every chunk are grouped by owner first.
+type negativeCache map[ownerRef]time.Time
+
+func (c negativeCache) missing(key ownerRef) bool {
+	until, ok := c[key]
+	return ok && time.Now().Before(until)
+}
+
+// Sweep runs a single pass over dependentGVK objects labeled with ownership
+// of ownerGVK, deleting the ones whose owner Get returns NotFound. cache may
+// be nil, in which case every owner is looked up fresh.
+func (g *DependentGarbageCollector) Sweep(ctx context.Context, ownerGVK, dependentGVK schema.GroupVersionKind, cache negativeCache) error {
+	nameLabel := ownerLabelKey(ownerGVK.Group, ownerNameLabelSuffix)
+	nsLabel := ownerLabelKey(ownerGVK.Group, ownerNamespaceLabelSuffix)
+	uidLabel := ownerLabelKey(ownerGVK.Group, ownerUIDLabelSuffix)
+
+	byOwner := map[ownerRef][]unstructured.Unstructured{}
+
+	dependentListGVK := schema.GroupVersionKind{
+		Group:   dependentGVK.Group,
+		Version: dependentGVK.Version,
+		Kind:    dependentGVK.Kind + "List",
+	}
+	opts := []client.ListOption{
+		client.HasLabels{nameLabel, nsLabel, uidLabel},
+		client.Limit(g.chunkSize()),
+	}
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(dependentListGVK)
+		if err := g.Client.List(ctx, list, opts...); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			labels := item.GetLabels()
+			key := ownerRef{Namespace: labels[nsLabel], Name: labels[nameLabel], UID: labels[uidLabel]}
+			byOwner[key] = append(byOwner[key], item)
+		}
+		if list.GetContinue() == "" {
+			break
+		}
+		opts = append(opts[:2:2], client.Continue(list.GetContinue()))
+	}
+
+	for key, dependents := range byOwner {
+		if cache != nil && cache.missing(key) {
+			g.deleteAll(ctx, dependents)
+			continue
+		}
+
+		owner := &unstructured.Unstructured{}
+		owner.SetGroupVersionKind(ownerGVK)
+		err := g.Client.Get(ctx, client.ObjectKey{Namespace: key.Namespace, Name: key.Name}, owner)
+		switch {
+		case apierrors.IsNotFound(err):
+			if cache != nil {
+				cache[key] = time.Now().Add(g.negativeCacheTTL())
+			}
+			g.deleteAll(ctx, dependents)
+		case err != nil:
+			gcLog.Error(err, "failed to look up owner, will retry next sweep", "owner", key)
+		default:
+			if cache != nil {
+				delete(cache, key)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *DependentGarbageCollector) deleteAll(ctx context.Context, dependents []unstructured.Unstructured) {
+	for i := range dependents {
+		if err := g.Client.Delete(ctx, &dependents[i]); err != nil && !apierrors.IsNotFound(err) {
+			gcLog.Error(err, "failed to delete orphaned dependent",
+				"name", dependents[i].GetName(), "namespace", dependents[i].GetNamespace())
+		}
+	}
+}
+
+func (g *DependentGarbageCollector) chunkSize() int64 {
+	if g.ChunkSize <= 0 {
+		return defaultGCChunkSize
+	}
+	return g.ChunkSize
+}
+
+func (g *DependentGarbageCollector) negativeCacheTTL() time.Duration {
+	if g.NegativeCacheTTL <= 0 {
+		return defaultGCNegativeCacheTTL
+	}
+	return g.NegativeCacheTTL
+}
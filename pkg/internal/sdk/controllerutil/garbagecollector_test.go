@@ -0,0 +1,70 @@
+package controllerutil_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/joelanford/helm-operator/pkg/internal/sdk/controllerutil"
+)
+
+var _ = Describe("DependentGarbageCollector", func() {
+	var (
+		ctx          context.Context
+		ownerGVK     = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Owner"}
+		dependentGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ClusterDependent"}
+		owner        *unstructured.Unstructured
+		dependent    *unstructured.Unstructured
+		c            client.Client
+		gc           *DependentGarbageCollector
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		owner = createObject(ownerGVK, types.NamespacedName{Namespace: "ns1", Name: "owner"})
+		owner.SetUID(types.UID("owner-uid"))
+
+		dependent = createObject(dependentGVK, types.NamespacedName{Name: "dependent"})
+		Expect(SetOwnerLabels(owner, dependent)).To(Succeed())
+
+		s := runtime.NewScheme()
+		s.AddKnownTypeWithName(ownerGVK, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(dependentGVK, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(schema.GroupVersionKind{Group: dependentGVK.Group, Version: dependentGVK.Version, Kind: dependentGVK.Kind + "List"}, &unstructured.UnstructuredList{})
+		c = fake.NewFakeClientWithScheme(s, owner, dependent)
+		gc = NewDependentGarbageCollector(c)
+	})
+
+	When("the owner still exists", func() {
+		It("leaves the dependent alone", func() {
+			Expect(gc.Sweep(ctx, ownerGVK, dependentGVK, nil)).To(Succeed())
+
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(dependentGVK)
+			Expect(c.Get(ctx, client.ObjectKey{Name: "dependent"}, got)).To(Succeed())
+		})
+	})
+
+	When("the owner has been deleted", func() {
+		BeforeEach(func() {
+			Expect(c.Delete(ctx, owner)).To(Succeed())
+		})
+
+		It("deletes the orphaned dependent", func() {
+			Expect(gc.Sweep(ctx, ownerGVK, dependentGVK, nil)).To(Succeed())
+
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(dependentGVK)
+			err := c.Get(ctx, client.ObjectKey{Name: "dependent"}, got)
+			Expect(err).To(HaveOccurred())
+			Expect(client.IgnoreNotFound(err)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package controllerutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WaitForDeletionMetadata is WaitForDeletion for callers that only need to
+// know that the object is gone, not what was in it. It polls with a
+// PartialObjectMetadata Get instead of round-tripping the full object, which
+// matters for large CRs and for cluster-scoped dependents being reaped in
+// bulk. Callers must supply gvk explicitly since a PartialObjectMetadata Get
+// doesn't decode TypeMeta.
+func WaitForDeletionMetadata(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, key types.NamespacedName) error {
+	return wait.PollImmediateUntil(time.Millisecond*100, func() (bool, error) {
+		partial := &metav1.PartialObjectMetadata{}
+		partial.SetGroupVersionKind(gvk)
+		err := c.Get(ctx, key, partial)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}, ctx.Done())
+}
+
+// WatchDeletionMetadata is WaitForDeletionMetadata for callers reaping many
+// releases at once: instead of each caller polling its own object, they
+// share a single metadata-only informer for gvk and are notified as soon as
+// their object's delete event arrives.
+func WatchDeletionMetadata(ctx context.Context, informers cache.Cache, gvk schema.GroupVersionKind, key types.NamespacedName) error {
+	partial := &metav1.PartialObjectMetadata{}
+	partial.SetGroupVersionKind(gvk)
+
+	informer, err := informers.GetInformer(ctx, partial)
+	if err != nil {
+		return fmt.Errorf("get metadata informer for %s: %v", gvk, err)
+	}
+
+	deleted := make(chan struct{})
+	var once sync.Once
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			if matchesKey(obj, key) {
+				once.Do(func() { close(deleted) })
+			}
+		},
+	})
+
+	select {
+	case <-deleted:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func matchesKey(obj interface{}, key types.NamespacedName) bool {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	return accessor.GetNamespace() == key.Namespace && accessor.GetName() == key.Name
+}
+
+// AddFinalizer adds finalizer to obj, persisting the change with a JSON
+// patch that tests the finalizer list it read before setting the new one.
+// That guards against two reconcilers racing to add their own finalizer and
+// clobbering each other the way a blind Update of the whole object would.
+func AddFinalizer(ctx context.Context, c client.Client, obj runtime.Object, finalizer string) error {
+	return patchFinalizers(ctx, c, obj, func(existing []string) []string {
+		for _, f := range existing {
+			if f == finalizer {
+				return existing
+			}
+		}
+		return append(append([]string{}, existing...), finalizer)
+	})
+}
+
+// RemoveFinalizer removes finalizer from obj using the same test-and-set
+// JSON patch as AddFinalizer.
+func RemoveFinalizer(ctx context.Context, c client.Client, obj runtime.Object, finalizer string) error {
+	return patchFinalizers(ctx, c, obj, func(existing []string) []string {
+		out := make([]string, 0, len(existing))
+		for _, f := range existing {
+			if f != finalizer {
+				out = append(out, f)
+			}
+		}
+		return out
+	})
+}
+
+func patchFinalizers(ctx context.Context, c client.Client, obj runtime.Object, mutate func([]string) []string) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Errorf("get accessor: %v", err)
+	}
+
+	before := accessor.GetFinalizers()
+	after := mutate(before)
+
+	patch, err := json.Marshal([]jsonPatchOperation{
+		{Op: "test", Path: "/metadata/finalizers", Value: before},
+		{Op: "add", Path: "/metadata/finalizers", Value: after},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal finalizer patch: %v", err)
+	}
+	if err := c.Patch(ctx, obj, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+		return fmt.Errorf("patch finalizers: %v", err)
+	}
+	accessor.SetFinalizers(after)
+	return nil
+}
+
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
@@ -0,0 +1,93 @@
+package controllerutil_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/joelanford/helm-operator/pkg/internal/sdk/controllerutil"
+)
+
+var _ = Describe("WaitForDeletionMetadata", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		pod    *v1.Pod
+		gvk    = schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+		key    = types.NamespacedName{Namespace: "testNamespace", Name: "testName"}
+		c      client.Client
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		pod = &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+		c = fake.NewFakeClientWithScheme(scheme.Scheme, pod)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should be cancellable", func() {
+		cancel()
+		Expect(WaitForDeletionMetadata(ctx, c, gvk, key)).To(MatchError(wait.ErrWaitTimeout))
+	})
+
+	It("should succeed after the object is deleted", func() {
+		Expect(c.Delete(ctx, pod)).To(Succeed())
+		Expect(WaitForDeletionMetadata(ctx, c, gvk, key)).To(Succeed())
+	})
+})
+
+var _ = Describe("AddFinalizer and RemoveFinalizer", func() {
+	var (
+		ctx context.Context
+		pod *v1.Pod
+		c   client.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		pod = &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "testName", Namespace: "testNamespace"}}
+		c = fake.NewFakeClientWithScheme(scheme.Scheme, pod)
+	})
+
+	It("adds a finalizer that isn't already present", func() {
+		Expect(AddFinalizer(ctx, c, pod, "my-finalizer")).To(Succeed())
+		Expect(pod.GetFinalizers()).To(ConsistOf("my-finalizer"))
+
+		got := &v1.Pod{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "testNamespace", Name: "testName"}, got)).To(Succeed())
+		Expect(got.GetFinalizers()).To(ConsistOf("my-finalizer"))
+	})
+
+	It("is a no-op when the finalizer is already present", func() {
+		pod.SetFinalizers([]string{"my-finalizer"})
+		Expect(c.Update(ctx, pod)).To(Succeed())
+
+		Expect(AddFinalizer(ctx, c, pod, "my-finalizer")).To(Succeed())
+		Expect(pod.GetFinalizers()).To(ConsistOf("my-finalizer"))
+	})
+
+	It("removes a finalizer", func() {
+		pod.SetFinalizers([]string{"my-finalizer", "keep-me"})
+		Expect(c.Update(ctx, pod)).To(Succeed())
+
+		Expect(RemoveFinalizer(ctx, c, pod, "my-finalizer")).To(Succeed())
+		Expect(pod.GetFinalizers()).To(ConsistOf("keep-me"))
+	})
+
+	It("fails when the object doesn't have type metadata", func() {
+		invalid := &object{TypeMeta: metav1.TypeMeta{}}
+		Expect(AddFinalizer(ctx, c, invalid, "my-finalizer")).NotTo(Succeed())
+	})
+})
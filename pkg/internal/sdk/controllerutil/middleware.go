@@ -0,0 +1,30 @@
+package controllerutil
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// ClientMiddleware wraps a client.Client, letting a caller intercept every
+// verb (Get/List/Create/Update/Patch/Delete/DeleteAllOf/Status) sent through
+// the wrapped client. Middlewares may mutate the object or options passed
+// through, rewrite its GVK, or short-circuit the call entirely.
+type ClientMiddleware interface {
+	Wrap(client.Client) client.Client
+}
+
+// ClientMiddlewareFunc adapts a function to a ClientMiddleware.
+type ClientMiddlewareFunc func(client.Client) client.Client
+
+// Wrap implements ClientMiddleware.
+func (f ClientMiddlewareFunc) Wrap(c client.Client) client.Client { return f(c) }
+
+// Chain composes middlewares into a single ClientMiddleware. Middlewares are
+// applied in the order given, so the first middleware wraps the raw
+// delegate and the last middleware ends up outermost: it is the first to
+// see a call and the last to see its result.
+func Chain(middlewares ...ClientMiddleware) ClientMiddleware {
+	return ClientMiddlewareFunc(func(c client.Client) client.Client {
+		for _, m := range middlewares {
+			c = m.Wrap(c)
+		}
+		return c
+	})
+}
@@ -0,0 +1,58 @@
+package controllerutil
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ForceDryRun returns a ClientMiddleware that adds client.DryRunAll to every
+// write it sees, regardless of what the caller passed. It's used on the
+// client the release diffing path reconciles against, so rendering a diff
+// never has side effects on the cluster.
+func ForceDryRun() ClientMiddleware {
+	return ClientMiddlewareFunc(func(c client.Client) client.Client {
+		return &dryRunClient{Client: c}
+	})
+}
+
+type dryRunClient struct {
+	client.Client
+}
+
+func (d *dryRunClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	return d.Client.Create(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (d *dryRunClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return d.Client.Update(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (d *dryRunClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return d.Client.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...)
+}
+
+func (d *dryRunClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	return d.Client.Delete(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (d *dryRunClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	return d.Client.DeleteAllOf(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (d *dryRunClient) Status() client.StatusWriter {
+	return &dryRunStatusWriter{delegate: d.Client.Status()}
+}
+
+type dryRunStatusWriter struct {
+	delegate client.StatusWriter
+}
+
+func (w *dryRunStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return w.delegate.Update(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (w *dryRunStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return w.delegate.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...)
+}
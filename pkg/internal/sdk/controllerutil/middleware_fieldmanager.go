@@ -0,0 +1,52 @@
+package controllerutil
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StampFieldManager returns a ClientMiddleware that stamps every write with
+// client.FieldOwner(manager), so server-side apply ownership stays
+// consistent across reconciles regardless of which code path issued the
+// write.
+func StampFieldManager(manager string) ClientMiddleware {
+	return ClientMiddlewareFunc(func(c client.Client) client.Client {
+		return &fieldManagerClient{Client: c, manager: manager}
+	})
+}
+
+type fieldManagerClient struct {
+	client.Client
+	manager string
+}
+
+func (f *fieldManagerClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	return f.Client.Create(ctx, obj, append(opts, client.FieldOwner(f.manager))...)
+}
+
+func (f *fieldManagerClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return f.Client.Update(ctx, obj, append(opts, client.FieldOwner(f.manager))...)
+}
+
+func (f *fieldManagerClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return f.Client.Patch(ctx, obj, patch, append(opts, client.FieldOwner(f.manager))...)
+}
+
+func (f *fieldManagerClient) Status() client.StatusWriter {
+	return &fieldManagerStatusWriter{delegate: f.Client.Status(), manager: f.manager}
+}
+
+type fieldManagerStatusWriter struct {
+	delegate client.StatusWriter
+	manager  string
+}
+
+func (w *fieldManagerStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return w.delegate.Update(ctx, obj, append(opts, client.FieldOwner(w.manager))...)
+}
+
+func (w *fieldManagerStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return w.delegate.Patch(ctx, obj, patch, append(opts, client.FieldOwner(w.manager))...)
+}
@@ -0,0 +1,109 @@
+package controllerutil
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RewriteAPIGroup returns a ClientMiddleware that rewrites the API group of
+// every object it sends to the wrapped client from "from" to "to", and back
+// again once that call returns. It lets a single operator binary reconcile against
+// "helm.example.com" while actually talking to the API server as
+// "helm.other.example.com" (or vice versa), without recompiling for each
+// installation's chosen CRD group.
+func RewriteAPIGroup(from, to string) ClientMiddleware {
+	return ClientMiddlewareFunc(func(c client.Client) client.Client {
+		return &groupSuffixRewriter{Client: c, from: from, to: to}
+	})
+}
+
+type groupSuffixRewriter struct {
+	client.Client
+	from, to string
+}
+
+func (r *groupSuffixRewriter) rewrite(obj runtime.Object, from, to string) {
+	kind := obj.GetObjectKind()
+	gvk := kind.GroupVersionKind()
+	if gvk.Group == from {
+		gvk.Group = to
+		kind.SetGroupVersionKind(gvk)
+	}
+}
+
+func (r *groupSuffixRewriter) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	r.rewrite(obj, r.from, r.to)
+	defer r.rewrite(obj, r.to, r.from)
+	return r.Client.Get(ctx, key, obj)
+}
+
+func (r *groupSuffixRewriter) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	r.rewrite(list, r.from, r.to)
+	err := r.Client.List(ctx, list, opts...)
+	r.rewrite(list, r.to, r.from)
+	if err != nil {
+		return err
+	}
+	// The list's own GVK is rewritten above, but List populates each item
+	// independently, so every item still carries the wire-side group too.
+	if u, ok := list.(*unstructured.UnstructuredList); ok {
+		for i := range u.Items {
+			r.rewrite(&u.Items[i], r.to, r.from)
+		}
+	}
+	return nil
+}
+
+func (r *groupSuffixRewriter) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	r.rewrite(obj, r.from, r.to)
+	defer r.rewrite(obj, r.to, r.from)
+	return r.Client.Create(ctx, obj, opts...)
+}
+
+func (r *groupSuffixRewriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	r.rewrite(obj, r.from, r.to)
+	defer r.rewrite(obj, r.to, r.from)
+	return r.Client.Update(ctx, obj, opts...)
+}
+
+func (r *groupSuffixRewriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	r.rewrite(obj, r.from, r.to)
+	defer r.rewrite(obj, r.to, r.from)
+	return r.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (r *groupSuffixRewriter) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	r.rewrite(obj, r.from, r.to)
+	defer r.rewrite(obj, r.to, r.from)
+	return r.Client.Delete(ctx, obj, opts...)
+}
+
+func (r *groupSuffixRewriter) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	r.rewrite(obj, r.from, r.to)
+	defer r.rewrite(obj, r.to, r.from)
+	return r.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (r *groupSuffixRewriter) Status() client.StatusWriter {
+	return &groupSuffixRewriterStatusWriter{delegate: r.Client.Status(), rewriter: r}
+}
+
+type groupSuffixRewriterStatusWriter struct {
+	delegate client.StatusWriter
+	rewriter *groupSuffixRewriter
+}
+
+func (w *groupSuffixRewriterStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	w.rewriter.rewrite(obj, w.rewriter.from, w.rewriter.to)
+	defer w.rewriter.rewrite(obj, w.rewriter.to, w.rewriter.from)
+	return w.delegate.Update(ctx, obj, opts...)
+}
+
+func (w *groupSuffixRewriterStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.rewriter.rewrite(obj, w.rewriter.from, w.rewriter.to)
+	defer w.rewriter.rewrite(obj, w.rewriter.to, w.rewriter.from)
+	return w.delegate.Patch(ctx, obj, patch, opts...)
+}
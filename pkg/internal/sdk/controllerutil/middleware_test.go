@@ -0,0 +1,123 @@
+package controllerutil_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/joelanford/helm-operator/pkg/internal/sdk/controllerutil"
+)
+
+// tracingMiddleware records name in trace every time Get is called, then
+// delegates, so tests can observe the order middlewares run in.
+type tracingMiddleware struct {
+	name  string
+	trace *[]string
+}
+
+func (m tracingMiddleware) Wrap(c client.Client) client.Client {
+	return &tracingClient{Client: c, name: m.name, trace: m.trace}
+}
+
+type tracingClient struct {
+	client.Client
+	name  string
+	trace *[]string
+}
+
+func (c *tracingClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	*c.trace = append(*c.trace, c.name)
+	return c.Client.Get(ctx, key, obj)
+}
+
+var _ = Describe("ClientMiddleware", func() {
+	Describe("Chain", func() {
+		It("applies middlewares in order, so the last one is outermost", func() {
+			var trace []string
+			gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Kind"}
+			s := runtime.NewScheme()
+			s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+			obj := createObject(gvk, types.NamespacedName{Name: "obj"})
+			delegate := fake.NewFakeClientWithScheme(s, obj)
+
+			chain := Chain(
+				tracingMiddleware{name: "first", trace: &trace},
+				tracingMiddleware{name: "second", trace: &trace},
+			)
+			wrapped := chain.Wrap(delegate)
+
+			Expect(wrapped.Get(context.Background(), client.ObjectKey{Name: "obj"}, createObject(gvk, types.NamespacedName{}))).To(Succeed())
+			Expect(trace).To(Equal([]string{"second", "first"}))
+		})
+	})
+
+	Describe("RewriteAPIGroup", func() {
+		It("rewrites the group on the way out and restores it on the way back", func() {
+			wireGVK := schema.GroupVersionKind{Group: "helm.example.com", Version: "v1", Kind: "Kind"}
+			canonicalGVK := schema.GroupVersionKind{Group: "helm.other.example.com", Version: "v1", Kind: "Kind"}
+			s := runtime.NewScheme()
+			s.AddKnownTypeWithName(wireGVK, &unstructured.Unstructured{})
+			delegate := fake.NewFakeClientWithScheme(s, createObject(wireGVK, types.NamespacedName{Name: "obj"}))
+
+			wrapped := RewriteAPIGroup(canonicalGVK.Group, wireGVK.Group).Wrap(delegate)
+
+			obj := createObject(canonicalGVK, types.NamespacedName{})
+			Expect(wrapped.Get(context.Background(), client.ObjectKey{Name: "obj"}, obj)).To(Succeed())
+			Expect(obj.GroupVersionKind()).To(Equal(canonicalGVK))
+		})
+
+		It("rewrites every item's group back too, not just the list's own", func() {
+			wireGVK := schema.GroupVersionKind{Group: "helm.example.com", Version: "v1", Kind: "Kind"}
+			canonicalGVK := schema.GroupVersionKind{Group: "helm.other.example.com", Version: "v1", Kind: "Kind"}
+			s := runtime.NewScheme()
+			s.AddKnownTypeWithName(wireGVK, &unstructured.Unstructured{})
+			s.AddKnownTypeWithName(schema.GroupVersionKind{Group: wireGVK.Group, Version: wireGVK.Version, Kind: "KindList"}, &unstructured.UnstructuredList{})
+			delegate := fake.NewFakeClientWithScheme(s, createObject(wireGVK, types.NamespacedName{Name: "obj"}))
+
+			wrapped := RewriteAPIGroup(canonicalGVK.Group, wireGVK.Group).Wrap(delegate)
+
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(schema.GroupVersionKind{Group: canonicalGVK.Group, Version: canonicalGVK.Version, Kind: "KindList"})
+			Expect(wrapped.List(context.Background(), list)).To(Succeed())
+			Expect(list.Items).To(HaveLen(1))
+			Expect(list.Items[0].GroupVersionKind()).To(Equal(canonicalGVK))
+		})
+	})
+
+	Describe("ForceDryRun", func() {
+		It("creates the object as a dry run, so it isn't persisted", func() {
+			gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Kind"}
+			s := runtime.NewScheme()
+			s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+			delegate := fake.NewFakeClientWithScheme(s)
+			wrapped := ForceDryRun().Wrap(delegate)
+
+			obj := createObject(gvk, types.NamespacedName{Name: "obj"})
+			Expect(wrapped.Create(context.Background(), obj)).To(Succeed())
+
+			got := createObject(gvk, types.NamespacedName{})
+			err := delegate.Get(context.Background(), client.ObjectKey{Name: "obj"}, got)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("StampFieldManager", func() {
+		It("does not error when stamping a create", func() {
+			gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Kind"}
+			s := runtime.NewScheme()
+			s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+			delegate := fake.NewFakeClientWithScheme(s)
+			wrapped := StampFieldManager("helm-operator").Wrap(delegate)
+
+			obj := createObject(gvk, types.NamespacedName{Name: "obj"})
+			Expect(wrapped.Create(context.Background(), obj)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,180 @@
+package controllerutil
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewNamespacedClient wraps c so that every namespace-scoped object it
+// touches is forced into namespace ns; cluster-scoped objects pass through
+// untouched. rm is used to tell the two apart.
+//
+// It exists so that rendered helm manifests which omit "namespace:" land in
+// the HelmRelease's namespace on install instead of "default". An object
+// that already names a different namespace is not moved: the call fails
+// with a ConflictingNamespaceError. Like
+// SupportsOwnerReference, scope is decided from each object's own
+// GroupVersionKind, so it works with unstructured.Unstructured and
+// metav1.PartialObjectMetadata without any extra plumbing.
+func NewNamespacedClient(c client.Client, rm meta.RESTMapper, ns string) client.Client {
+	return &namespacedClient{Client: c, restMapper: rm, namespace: ns}
+}
+
+// ConflictingNamespaceError is returned when a caller supplies an
+// already-namespaced object, or Get key, whose namespace doesn't match the
+// namespace a namespacedClient enforces.
+type ConflictingNamespaceError struct {
+	Got    string
+	Wanted string
+}
+
+func (e *ConflictingNamespaceError) Error() string {
+	return fmt.Sprintf("object has namespace %q, expected %q", e.Got, e.Wanted)
+}
+
+type namespacedClient struct {
+	client.Client
+	restMapper meta.RESTMapper
+	namespace  string
+}
+
+func (n *namespacedClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	namespaced, err := n.isNamespacedObj(obj)
+	if err != nil {
+		return err
+	}
+	if namespaced {
+		if key.Namespace != "" && key.Namespace != n.namespace {
+			return &ConflictingNamespaceError{Got: key.Namespace, Wanted: n.namespace}
+		}
+		key.Namespace = n.namespace
+	}
+	return n.Client.Get(ctx, key, obj)
+}
+
+func (n *namespacedClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	namespaced, err := n.isNamespaced(itemGVK(list))
+	if err != nil {
+		return err
+	}
+	if namespaced {
+		opts = append(opts, client.InNamespace(n.namespace))
+	}
+	return n.Client.List(ctx, list, opts...)
+}
+
+func (n *namespacedClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if err := n.enforceNamespace(obj); err != nil {
+		return err
+	}
+	return n.Client.Create(ctx, obj, opts...)
+}
+
+func (n *namespacedClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if err := n.enforceNamespace(obj); err != nil {
+		return err
+	}
+	return n.Client.Update(ctx, obj, opts...)
+}
+
+func (n *namespacedClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := n.enforceNamespace(obj); err != nil {
+		return err
+	}
+	return n.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (n *namespacedClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	if err := n.enforceNamespace(obj); err != nil {
+		return err
+	}
+	return n.Client.Delete(ctx, obj, opts...)
+}
+
+func (n *namespacedClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	namespaced, err := n.isNamespacedObj(obj)
+	if err != nil {
+		return err
+	}
+	if namespaced {
+		opts = append(opts, client.InNamespace(n.namespace))
+	}
+	return n.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (n *namespacedClient) Status() client.StatusWriter {
+	return &namespacedStatusWriter{delegate: n.Client.Status(), client: n}
+}
+
+type namespacedStatusWriter struct {
+	delegate client.StatusWriter
+	client   *namespacedClient
+}
+
+func (w *namespacedStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if err := w.client.enforceNamespace(obj); err != nil {
+		return err
+	}
+	return w.delegate.Update(ctx, obj, opts...)
+}
+
+func (w *namespacedStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := w.client.enforceNamespace(obj); err != nil {
+		return err
+	}
+	return w.delegate.Patch(ctx, obj, patch, opts...)
+}
+
+// enforceNamespace forces obj's own metadata.namespace to n.namespace when
+// obj's GVK is namespace-scoped, failing if obj already names a different
+// namespace. Cluster-scoped objects are left untouched.
+func (n *namespacedClient) enforceNamespace(obj runtime.Object) error {
+	namespaced, err := n.isNamespacedObj(obj)
+	if err != nil {
+		return err
+	}
+	if !namespaced {
+		return nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Errorf("get accessor: %v", err)
+	}
+	if ns := accessor.GetNamespace(); ns != "" && ns != n.namespace {
+		return &ConflictingNamespaceError{Got: ns, Wanted: n.namespace}
+	}
+	accessor.SetNamespace(n.namespace)
+	return nil
+}
+
+func (n *namespacedClient) isNamespacedObj(obj runtime.Object) (bool, error) {
+	return n.isNamespaced(obj.GetObjectKind().GroupVersionKind())
+}
+
+func (n *namespacedClient) isNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	if gvk.Empty() {
+		return false, fmt.Errorf("unable to determine GVK of object")
+	}
+	mapping, err := n.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, fmt.Errorf("get REST mapping for %s: %v", gvk, err)
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// itemGVK returns the GVK of the items in a list object, e.g. "Pod" for a
+// PodList, by trimming the conventional "List" suffix off the list's own
+// Kind.
+func itemGVK(list runtime.Object) schema.GroupVersionKind {
+	gvk := list.GetObjectKind().GroupVersionKind()
+	const suffix = "List"
+	if len(gvk.Kind) > len(suffix) && gvk.Kind[len(gvk.Kind)-len(suffix):] == suffix {
+		gvk.Kind = gvk.Kind[:len(gvk.Kind)-len(suffix)]
+	}
+	return gvk
+}
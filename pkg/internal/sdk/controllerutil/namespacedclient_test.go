@@ -0,0 +1,89 @@
+package controllerutil_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/joelanford/helm-operator/pkg/internal/sdk/controllerutil"
+)
+
+var _ = Describe("NewNamespacedClient", func() {
+	var (
+		clusterScoped   = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "ClusterScoped"}
+		namespaceScoped = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "NamespaceScoped"}
+		rm              *meta.DefaultRESTMapper
+		delegate        client.Client
+		nsClient        client.Client
+		ctx             = context.Background()
+	)
+
+	BeforeEach(func() {
+		rm = meta.NewDefaultRESTMapper([]schema.GroupVersion{})
+		rm.Add(clusterScoped, meta.RESTScopeRoot)
+		rm.Add(namespaceScoped, meta.RESTScopeNamespace)
+
+		s := runtime.NewScheme()
+		s.AddKnownTypeWithName(clusterScoped, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(namespaceScoped, &unstructured.Unstructured{})
+		delegate = fake.NewFakeClientWithScheme(s)
+		nsClient = NewNamespacedClient(delegate, rm, "wanted-ns")
+	})
+
+	When("the object is cluster-scoped", func() {
+		It("passes the object through untouched", func() {
+			obj := createObject(clusterScoped, types.NamespacedName{Name: "obj"})
+			Expect(nsClient.Create(ctx, obj)).To(Succeed())
+			Expect(obj.GetNamespace()).To(BeEmpty())
+		})
+	})
+
+	When("the object is namespace-scoped", func() {
+		It("injects the configured namespace when none is set", func() {
+			obj := createObject(namespaceScoped, types.NamespacedName{Name: "obj"})
+			Expect(nsClient.Create(ctx, obj)).To(Succeed())
+			Expect(obj.GetNamespace()).To(Equal("wanted-ns"))
+		})
+
+		It("leaves a matching namespace alone", func() {
+			obj := createObject(namespaceScoped, types.NamespacedName{Namespace: "wanted-ns", Name: "obj"})
+			Expect(nsClient.Create(ctx, obj)).To(Succeed())
+			Expect(obj.GetNamespace()).To(Equal("wanted-ns"))
+		})
+
+		It("fails with a typed error on a conflicting namespace", func() {
+			obj := createObject(namespaceScoped, types.NamespacedName{Namespace: "other-ns", Name: "obj"})
+			err := nsClient.Create(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			var conflict *ConflictingNamespaceError
+			Expect(errors.As(err, &conflict)).To(BeTrue())
+			Expect(conflict.Got).To(Equal("other-ns"))
+			Expect(conflict.Wanted).To(Equal("wanted-ns"))
+		})
+
+		It("rejects a Get for a conflicting namespace key", func() {
+			err := nsClient.Get(ctx, client.ObjectKey{Namespace: "other-ns", Name: "obj"}, createObject(namespaceScoped, types.NamespacedName{}))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Status()", func() {
+		It("enforces the namespace on status updates too", func() {
+			obj := createObject(namespaceScoped, types.NamespacedName{Name: "obj"})
+			Expect(nsClient.Create(ctx, obj)).To(Succeed())
+
+			other := createObject(namespaceScoped, types.NamespacedName{Namespace: "other-ns", Name: "obj"})
+			err := nsClient.Status().Update(ctx, other)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,96 @@
+package controllerutil
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Label suffixes used to record ownership on a dependent when a native
+// metadata.ownerReferences entry can't express it. The full label key is
+// "<owner group>/<suffix>", e.g. "cache.example.com/owner-name".
+const (
+	ownerNameLabelSuffix      = "owner-name"
+	ownerNamespaceLabelSuffix = "owner-namespace"
+	ownerUIDLabelSuffix       = "owner-uid"
+)
+
+func ownerLabelKey(ownerGroup, suffix string) string {
+	return fmt.Sprintf("%s/%s", ownerGroup, suffix)
+}
+
+// SetOwnerLabels stamps dependent with labels identifying owner. It is the
+// fallback used in place of a native owner reference when
+// SupportsOwnerReference reports that one can't be used, e.g. because owner
+// is namespace-scoped and dependent is cluster-scoped or lives in a
+// different namespace.
+func SetOwnerLabels(owner, dependent runtime.Object) error {
+	ownerGVK := owner.GetObjectKind().GroupVersionKind()
+	if ownerGVK.Empty() {
+		return fmt.Errorf("unable to determine GVK of owner %T", owner)
+	}
+	ownerAccessor, err := meta.Accessor(owner)
+	if err != nil {
+		return fmt.Errorf("get accessor for owner: %v", err)
+	}
+	dependentAccessor, err := meta.Accessor(dependent)
+	if err != nil {
+		return fmt.Errorf("get accessor for dependent: %v", err)
+	}
+
+	labels := dependentAccessor.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ownerLabelKey(ownerGVK.Group, ownerNameLabelSuffix)] = ownerAccessor.GetName()
+	labels[ownerLabelKey(ownerGVK.Group, ownerNamespaceLabelSuffix)] = ownerAccessor.GetNamespace()
+	labels[ownerLabelKey(ownerGVK.Group, ownerUIDLabelSuffix)] = string(ownerAccessor.GetUID())
+	dependentAccessor.SetLabels(labels)
+	return nil
+}
+
+// MatchingOwnerLabels returns a list option that selects dependents
+// previously stamped by SetOwnerLabels for owner.
+func MatchingOwnerLabels(owner runtime.Object) (client.MatchingLabels, error) {
+	ownerGVK := owner.GetObjectKind().GroupVersionKind()
+	if ownerGVK.Empty() {
+		return nil, fmt.Errorf("unable to determine GVK of owner %T", owner)
+	}
+	ownerAccessor, err := meta.Accessor(owner)
+	if err != nil {
+		return nil, fmt.Errorf("get accessor for owner: %v", err)
+	}
+	return client.MatchingLabels{
+		ownerLabelKey(ownerGVK.Group, ownerNameLabelSuffix):      ownerAccessor.GetName(),
+		ownerLabelKey(ownerGVK.Group, ownerNamespaceLabelSuffix): ownerAccessor.GetNamespace(),
+		ownerLabelKey(ownerGVK.Group, ownerUIDLabelSuffix):       string(ownerAccessor.GetUID()),
+	}, nil
+}
+
+// AdoptDependent establishes ownership of dependent by owner, preferring a
+// native metadata.ownerReferences entry and falling back to the owner labels
+// stamped by SetOwnerLabels when SupportsOwnerReference reports that a
+// native reference can't be used.
+func AdoptDependent(rm meta.RESTMapper, scheme *runtime.Scheme, owner, dependent runtime.Object) error {
+	supportsOwnerRef, err := SupportsOwnerReference(rm, owner, dependent)
+	if err != nil {
+		return err
+	}
+	if !supportsOwnerRef {
+		return SetOwnerLabels(owner, dependent)
+	}
+
+	ownerAccessor, err := meta.Accessor(owner)
+	if err != nil {
+		return fmt.Errorf("get accessor for owner: %v", err)
+	}
+	dependentAccessor, err := meta.Accessor(dependent)
+	if err != nil {
+		return fmt.Errorf("get accessor for dependent: %v", err)
+	}
+	return ctrlutil.SetOwnerReference(ownerAccessor, dependentAccessor, scheme)
+}
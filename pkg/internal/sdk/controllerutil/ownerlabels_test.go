@@ -0,0 +1,85 @@
+package controllerutil_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/joelanford/helm-operator/pkg/internal/sdk/controllerutil"
+)
+
+var _ = Describe("Owner labels", func() {
+	var (
+		gvk   = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Kind"}
+		owner = createObject(gvk, types.NamespacedName{Namespace: "ns1", Name: "owner"})
+	)
+
+	BeforeEach(func() {
+		owner.SetUID(types.UID("owner-uid"))
+	})
+
+	Describe("SetOwnerLabels", func() {
+		It("stamps the dependent with the owner's name, namespace, and uid", func() {
+			dependent := createObject(gvk, types.NamespacedName{Namespace: "", Name: "dependent"})
+			Expect(SetOwnerLabels(owner, dependent)).To(Succeed())
+			Expect(dependent.GetLabels()).To(Equal(map[string]string{
+				"example.com/owner-name":      "owner",
+				"example.com/owner-namespace": "ns1",
+				"example.com/owner-uid":       "owner-uid",
+			}))
+		})
+
+		It("preserves the dependent's existing labels", func() {
+			dependent := createObject(gvk, types.NamespacedName{Namespace: "", Name: "dependent"})
+			dependent.SetLabels(map[string]string{"keep": "me"})
+			Expect(SetOwnerLabels(owner, dependent)).To(Succeed())
+			Expect(dependent.GetLabels()).To(HaveKeyWithValue("keep", "me"))
+		})
+	})
+
+	Describe("MatchingOwnerLabels", func() {
+		It("returns a selector matching the labels SetOwnerLabels would set", func() {
+			opts, err := MatchingOwnerLabels(owner)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts).To(Equal(client.MatchingLabels{
+				"example.com/owner-name":      "owner",
+				"example.com/owner-namespace": "ns1",
+				"example.com/owner-uid":       "owner-uid",
+			}))
+		})
+	})
+
+	Describe("AdoptDependent", func() {
+		It("falls back to owner labels when a native owner reference isn't supported", func() {
+			rm := meta.NewDefaultRESTMapper([]schema.GroupVersion{})
+			rm.Add(gvk, meta.RESTScopeNamespace)
+			clusterGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Cluster"}
+			rm.Add(clusterGVK, meta.RESTScopeRoot)
+			dependent := createObject(clusterGVK, types.NamespacedName{Name: "dependent"})
+
+			Expect(AdoptDependent(rm, runtime.NewScheme(), owner, dependent)).To(Succeed())
+			Expect(dependent.GetLabels()).To(HaveKeyWithValue("example.com/owner-uid", "owner-uid"))
+			Expect(dependent.GetOwnerReferences()).To(BeEmpty())
+		})
+
+		It("sets a native owner reference when one is supported", func() {
+			rm := meta.NewDefaultRESTMapper([]schema.GroupVersion{})
+			rm.Add(gvk, meta.RESTScopeNamespace)
+			dependent := createObject(gvk, types.NamespacedName{Namespace: "ns1", Name: "dependent"})
+
+			Expect(AdoptDependent(rm, runtime.NewScheme(), owner, dependent)).To(Succeed())
+			Expect(dependent.GetOwnerReferences()).To(ConsistOf(metav1.OwnerReference{
+				APIVersion: "example.com/v1",
+				Kind:       "Kind",
+				Name:       "owner",
+				UID:        "owner-uid",
+			}))
+			Expect(dependent.GetLabels()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,274 @@
+// Package reconciler wires the helpers in
+// pkg/internal/sdk/controllerutil together into a single Reconciler that
+// owns a helm-managed custom resource's dependents.
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/joelanford/helm-operator/pkg/internal/sdk/controllerutil"
+)
+
+// defaultFinalizer is added to the owner custom resource so its dependents
+// can be cleaned up before the owner is actually removed from etcd.
+const defaultFinalizer = "helm.joelanford.io/uninstall-release"
+
+// Reconciler reconciles a single GroupVersionKind of helm-managed custom
+// resource, adopting and garbage-collecting the dependents it installs.
+type Reconciler struct {
+	client        client.Client
+	restMapper    meta.RESTMapper
+	scheme        *runtime.Scheme
+	gvk           schema.GroupVersionKind
+	dependentGVKs []schema.GroupVersionKind
+	finalizer     string
+	middlewares   []controllerutil.ClientMiddleware
+
+	gc *controllerutil.DependentGarbageCollector
+}
+
+// Option configures a Reconciler built by New.
+type Option func(*Reconciler) error
+
+// New builds a Reconciler from opts. WithClient, WithRESTMapper, and
+// WithGroupVersionKind are required.
+func New(opts ...Option) (*Reconciler, error) {
+	r := &Reconciler{finalizer: defaultFinalizer}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+	if r.restMapper == nil {
+		return nil, fmt.Errorf("REST mapper is required")
+	}
+	if r.gvk.Empty() {
+		return nil, fmt.Errorf("group version kind is required")
+	}
+	if len(r.middlewares) > 0 {
+		r.client = controllerutil.Chain(r.middlewares...).Wrap(r.client)
+	}
+	r.gc = controllerutil.NewDependentGarbageCollector(r.client)
+	return r, nil
+}
+
+// WithClient sets the client the Reconciler uses to read and write both the
+// owner custom resource and its dependents.
+func WithClient(c client.Client) Option {
+	return func(r *Reconciler) error {
+		r.client = c
+		return nil
+	}
+}
+
+// WithRESTMapper sets the RESTMapper used to decide whether a dependent's
+// GVK is namespace- or cluster-scoped.
+func WithRESTMapper(rm meta.RESTMapper) Option {
+	return func(r *Reconciler) error {
+		r.restMapper = rm
+		return nil
+	}
+}
+
+// WithScheme sets the scheme used to populate native owner references.
+func WithScheme(s *runtime.Scheme) Option {
+	return func(r *Reconciler) error {
+		r.scheme = s
+		return nil
+	}
+}
+
+// WithGroupVersionKind sets the GVK of the owner custom resource this
+// Reconciler reconciles.
+func WithGroupVersionKind(gvk schema.GroupVersionKind) Option {
+	return func(r *Reconciler) error {
+		r.gvk = gvk
+		return nil
+	}
+}
+
+// WithDependentGroupVersionKinds registers the GVKs of resources this
+// Reconciler's owner may install as dependents. Each one gets its own
+// DependentGarbageCollector sweep once Reconcile has found an existing
+// owner, and
+// is swept synchronously for cleanup when the owner is deleted.
+func WithDependentGroupVersionKinds(gvks ...schema.GroupVersionKind) Option {
+	return func(r *Reconciler) error {
+		r.dependentGVKs = append(r.dependentGVKs, gvks...)
+		return nil
+	}
+}
+
+// WithClientMiddleware chains mw onto the client set by WithClient, in the
+// order given, so every Get/List/Create/Update/Patch/Delete/DeleteAllOf
+// this Reconciler issues passes through them first. Applied once in New,
+// regardless of where WithClientMiddleware appears relative to WithClient
+// in the opts list.
+func WithClientMiddleware(mw ...controllerutil.ClientMiddleware) Option {
+	return func(r *Reconciler) error {
+		r.middlewares = append(r.middlewares, mw...)
+		return nil
+	}
+}
+
+// WithFinalizer overrides the finalizer this Reconciler adds to the owner
+// custom resource so its dependents can be cleaned up before the owner is
+// removed. Defaults to defaultFinalizer.
+func WithFinalizer(name string) Option {
+	return func(r *Reconciler) error {
+		r.finalizer = name
+		return nil
+	}
+}
+
+// Reconcile fetches the owner custom resource named by req, starts a
+// DependentGarbageCollector sweep for each of its registered dependent
+// GVKs, and manages the finalizer that lets it clean those dependents up
+// before the owner is actually removed.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(r.gvk)
+	if err := r.client.Get(ctx, req.NamespacedName, owner); apierrors.IsNotFound(err) {
+		return reconcile.Result{}, nil
+	} else if err != nil {
+		return reconcile.Result{}, fmt.Errorf("get %s %s: %v", r.gvk, req.NamespacedName, err)
+	}
+
+	for _, dependentGVK := range r.dependentGVKs {
+		r.gc.Start(ctx, r.gvk, dependentGVK)
+	}
+
+	if !owner.GetDeletionTimestamp().IsZero() {
+		return reconcile.Result{}, r.cleanup(ctx, owner)
+	}
+
+	if err := controllerutil.AddFinalizer(ctx, r.client, owner, r.finalizer); err != nil {
+		return reconcile.Result{}, fmt.Errorf("add finalizer: %v", err)
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// cleanup deletes owner's dependents that were adopted via owner labels
+// (natively-owned dependents are left to Kubernetes' built-in garbage
+// collector, which already deletes them once owner is gone) and waits for
+// each to be gone before dropping the finalizer, so the owner isn't removed
+// while its dependents still exist.
+func (r *Reconciler) cleanup(ctx context.Context, owner *unstructured.Unstructured) error {
+	if !controllerutil.ContainsFinalizer(owner, r.finalizer) {
+		return nil
+	}
+
+	for _, dependentGVK := range r.dependentGVKs {
+		if err := r.deleteLabeledDependents(ctx, owner, dependentGVK); err != nil {
+			return fmt.Errorf("delete %s dependents: %v", dependentGVK, err)
+		}
+	}
+
+	if err := controllerutil.RemoveFinalizer(ctx, r.client, owner, r.finalizer); err != nil {
+		return fmt.Errorf("remove finalizer: %v", err)
+	}
+	return nil
+}
+
+func (r *Reconciler) deleteLabeledDependents(ctx context.Context, owner *unstructured.Unstructured, dependentGVK schema.GroupVersionKind) error {
+	matchingOwner, err := controllerutil.MatchingOwnerLabels(owner)
+	if err != nil {
+		return err
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: dependentGVK.Group, Version: dependentGVK.Version, Kind: dependentGVK.Kind + "List"})
+	if err := r.client.List(ctx, list, matchingOwner); err != nil {
+		return fmt.Errorf("list dependents: %v", err)
+	}
+
+	for i := range list.Items {
+		dependent := &list.Items[i]
+		key := client.ObjectKey{Namespace: dependent.GetNamespace(), Name: dependent.GetName()}
+		if err := r.client.Delete(ctx, dependent); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete %s: %v", key, err)
+		}
+		if err := controllerutil.WaitForDeletionMetadata(ctx, r.client, dependentGVK, key); err != nil {
+			return fmt.Errorf("wait for %s to be deleted: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// ApplyDependent creates or updates dependent as belonging to owner. It
+// establishes ownership via controllerutil.AdoptDependent, preferring a
+// native owner reference and falling back to owner labels when
+// SupportsOwnerReference says a native reference can't be used, and routes
+// the write through a namespace-enforcing client so a dependent manifest
+// that omits its namespace, or names the wrong one, still lands in owner's
+// namespace.
+func (r *Reconciler) ApplyDependent(ctx context.Context, owner, dependent runtime.Object) error {
+	ownerAccessor, err := meta.Accessor(owner)
+	if err != nil {
+		return fmt.Errorf("get accessor for owner: %v", err)
+	}
+
+	// Normalize dependent's namespace before AdoptDependent runs, so a
+	// manifest that omits "namespace:" (or gets it wrong) is judged by
+	// SupportsOwnerReference as if it already lived in owner's namespace,
+	// instead of being downgraded to the label-based fallback.
+	if err := r.normalizeDependentNamespace(ownerAccessor, dependent); err != nil {
+		return fmt.Errorf("normalize dependent namespace: %v", err)
+	}
+
+	if err := controllerutil.AdoptDependent(r.restMapper, r.scheme, owner, dependent); err != nil {
+		return fmt.Errorf("adopt dependent: %v", err)
+	}
+
+	c := controllerutil.NewNamespacedClient(r.client, r.restMapper, ownerAccessor.GetNamespace())
+
+	dependentAccessor, err := meta.Accessor(dependent)
+	if err != nil {
+		return fmt.Errorf("get accessor for dependent: %v", err)
+	}
+
+	existing := dependent.DeepCopyObject()
+	key := client.ObjectKey{Namespace: dependentAccessor.GetNamespace(), Name: dependentAccessor.GetName()}
+	err = c.Get(ctx, key, existing)
+	if apierrors.IsNotFound(err) {
+		return c.Create(ctx, dependent)
+	} else if err != nil {
+		return fmt.Errorf("get existing dependent: %v", err)
+	}
+	return c.Update(ctx, dependent)
+}
+
+// normalizeDependentNamespace sets dependent's namespace to owner's when
+// dependent's GVK is namespace-scoped, so a manifest that omits its
+// namespace (or gets it wrong) still ends up judged, and written, as if it
+// already belonged there. Cluster-scoped dependents are left untouched.
+func (r *Reconciler) normalizeDependentNamespace(ownerAccessor metav1.Object, dependent runtime.Object) error {
+	dependentGVK := dependent.GetObjectKind().GroupVersionKind()
+	mapping, err := r.restMapper.RESTMapping(dependentGVK.GroupKind(), dependentGVK.Version)
+	if err != nil {
+		return fmt.Errorf("get REST mapping for dependent %s: %v", dependentGVK, err)
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return nil
+	}
+	dependentAccessor, err := meta.Accessor(dependent)
+	if err != nil {
+		return fmt.Errorf("get accessor for dependent: %v", err)
+	}
+	dependentAccessor.SetNamespace(ownerAccessor.GetNamespace())
+	return nil
+}
@@ -0,0 +1,190 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/joelanford/helm-operator/pkg/internal/sdk/controllerutil"
+	. "github.com/joelanford/helm-operator/pkg/reconciler"
+)
+
+func createObject(gvk schema.GroupVersionKind, key types.NamespacedName) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(key.Name)
+	u.SetNamespace(key.Namespace)
+	return u
+}
+
+var _ = Describe("New", func() {
+	It("requires a client, REST mapper, and GVK", func() {
+		_, err := New()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("chains WithClientMiddleware onto the client regardless of option order", func() {
+		gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Owner"}
+		s := runtime.NewScheme()
+		s.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		rm := meta.NewDefaultRESTMapper([]schema.GroupVersion{})
+		rm.Add(gvk, meta.RESTScopeNamespace)
+
+		var trace []string
+		rec, err := New(
+			WithClientMiddleware(tracingMiddleware{name: "traced", trace: &trace}),
+			WithClient(fake.NewFakeClientWithScheme(s)),
+			WithRESTMapper(rm),
+			WithGroupVersionKind(gvk),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rec.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "missing"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trace).To(Equal([]string{"traced"}))
+	})
+})
+
+// tracingMiddleware records name in trace every time Get is called, then
+// delegates, so tests can observe whether a middleware set via
+// WithClientMiddleware actually intercepts calls made through the
+// Reconciler.
+type tracingMiddleware struct {
+	name  string
+	trace *[]string
+}
+
+func (m tracingMiddleware) Wrap(c client.Client) client.Client {
+	return &tracingClient{Client: c, name: m.name, trace: m.trace}
+}
+
+type tracingClient struct {
+	client.Client
+	name  string
+	trace *[]string
+}
+
+func (c *tracingClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	*c.trace = append(*c.trace, c.name)
+	return c.Client.Get(ctx, key, obj)
+}
+
+var _ = Describe("Reconciler", func() {
+	var (
+		ctx          context.Context
+		ownerGVK     = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Owner"}
+		dependentGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Dependent"}
+		s            *runtime.Scheme
+		rm           *meta.DefaultRESTMapper
+		fakeClient   client.Client
+		rec          *Reconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		s = runtime.NewScheme()
+		s.AddKnownTypeWithName(ownerGVK, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(dependentGVK, &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(schema.GroupVersionKind{Group: dependentGVK.Group, Version: dependentGVK.Version, Kind: dependentGVK.Kind + "List"}, &unstructured.UnstructuredList{})
+
+		rm = meta.NewDefaultRESTMapper([]schema.GroupVersion{})
+		rm.Add(ownerGVK, meta.RESTScopeNamespace)
+		rm.Add(dependentGVK, meta.RESTScopeNamespace)
+
+		fakeClient = fake.NewFakeClientWithScheme(s)
+
+		var err error
+		rec, err = New(
+			WithClient(fakeClient),
+			WithRESTMapper(rm),
+			WithScheme(s),
+			WithGroupVersionKind(ownerGVK),
+			WithDependentGroupVersionKinds(dependentGVK),
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Reconcile", func() {
+		It("is a no-op when the owner doesn't exist", func() {
+			result, err := rec.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "missing"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+		})
+
+		It("adds the finalizer when the owner doesn't have it yet", func() {
+			owner := createObject(ownerGVK, types.NamespacedName{Namespace: "ns1", Name: "owner"})
+			Expect(fakeClient.Create(ctx, owner)).To(Succeed())
+
+			_, err := rec.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "owner"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			got := createObject(ownerGVK, types.NamespacedName{})
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "owner"}, got)).To(Succeed())
+			Expect(got.GetFinalizers()).To(ContainElement("helm.joelanford.io/uninstall-release"))
+		})
+
+		When("the owner has a deletion timestamp", func() {
+			It("deletes label-adopted dependents and removes the finalizer", func() {
+				owner := createObject(ownerGVK, types.NamespacedName{Namespace: "ns1", Name: "owner"})
+				owner.SetFinalizers([]string{"helm.joelanford.io/uninstall-release"})
+				Expect(fakeClient.Create(ctx, owner)).To(Succeed())
+
+				dependent := createObject(dependentGVK, types.NamespacedName{Namespace: "ns1", Name: "dependent"})
+				Expect(controllerutil.SetOwnerLabels(owner, dependent)).To(Succeed())
+				Expect(fakeClient.Create(ctx, dependent)).To(Succeed())
+
+				now := metav1.Now()
+				owner.SetDeletionTimestamp(&now)
+				Expect(fakeClient.Update(ctx, owner)).To(Succeed())
+
+				_, err := rec.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "owner"}})
+				Expect(err).NotTo(HaveOccurred())
+
+				gotDependent := createObject(dependentGVK, types.NamespacedName{})
+				err = fakeClient.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "dependent"}, gotDependent)
+				Expect(err).To(HaveOccurred())
+
+				gotOwner := createObject(ownerGVK, types.NamespacedName{})
+				Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "owner"}, gotOwner)).To(Succeed())
+				Expect(gotOwner.GetFinalizers()).NotTo(ContainElement("helm.joelanford.io/uninstall-release"))
+			})
+		})
+	})
+
+	Describe("ApplyDependent", func() {
+		It("creates the dependent with a native owner reference when one is supported", func() {
+			owner := createObject(ownerGVK, types.NamespacedName{Namespace: "ns1", Name: "owner"})
+			owner.SetUID(types.UID("owner-uid"))
+			dependent := createObject(dependentGVK, types.NamespacedName{Namespace: "ns1", Name: "dependent"})
+
+			Expect(rec.ApplyDependent(ctx, owner, dependent)).To(Succeed())
+
+			got := createObject(dependentGVK, types.NamespacedName{})
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "dependent"}, got)).To(Succeed())
+			Expect(got.GetOwnerReferences()).NotTo(BeEmpty())
+		})
+
+		It("lands the dependent in owner's namespace even when the manifest omits one", func() {
+			owner := createObject(ownerGVK, types.NamespacedName{Namespace: "ns1", Name: "owner"})
+			owner.SetUID(types.UID("owner-uid"))
+			dependent := createObject(dependentGVK, types.NamespacedName{Namespace: "", Name: "dependent"})
+
+			Expect(rec.ApplyDependent(ctx, owner, dependent)).To(Succeed())
+
+			got := createObject(dependentGVK, types.NamespacedName{})
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "dependent"}, got)).To(Succeed())
+			Expect(got.GetOwnerReferences()).NotTo(BeEmpty())
+		})
+	})
+})